@@ -0,0 +1,249 @@
+package chatvotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSubscriptionExists is returned by EventBus.Subscribe when name is already taken.
+var ErrSubscriptionExists = errors.New("subscription already exists")
+
+// EventFilter decides whether ev should be delivered to a given subscriber.
+// A nil filter delivers every event.
+type EventFilter func(ev Event) bool
+
+// SlowConsumerPolicy decides what an EventBus does for a subscriber whose buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// PolicyDropOldest discards the oldest buffered event to make room for the new one.
+	PolicyDropOldest SlowConsumerPolicy = iota
+	// PolicyBlockWithTimeout blocks the publisher for up to BlockTimeout before giving up
+	// on delivering to this subscriber.
+	PolicyBlockWithTimeout
+	// PolicyDisconnect unsubscribes the subscriber as soon as it falls behind.
+	PolicyDisconnect
+)
+
+// subscriptionPendingBuffer is the capacity of a subscription's internal handoff
+// queue between Publish and its dispatch goroutine. It's deliberately generous and
+// independent of the subscriber's own requested buffer size, so a short burst of
+// Publish calls (the common case) never trips enqueue's drop-oldest fallback before
+// dispatch has even had a chance to apply the subscriber's real, configured policy
+// at the out-channel level; that fallback is a safety valve for a dispatch goroutine
+// stuck for a long time, not a substitute for the subscriber's own policy.
+const subscriptionPendingBuffer = 64
+
+// SubscribeOptions configures a subscription created via EventBus.SubscribeWithOptions.
+type SubscribeOptions struct {
+	// Filter, if set, restricts delivery to events for which it returns true.
+	Filter EventFilter
+	// Policy decides what happens when this subscriber's buffer is full. Defaults to
+	// PolicyDropOldest.
+	Policy SlowConsumerPolicy
+	// BlockTimeout is the duration PolicyBlockWithTimeout waits before giving up.
+	// Defaults to one second if zero.
+	BlockTimeout time.Duration
+}
+
+// subscription's out is only ever written to (and closed) by its own dispatch
+// goroutine, so applying a slow subscriber's policy there never holds up Publish
+// or any other subscriber. pending is the handoff point: Publish enqueues into it
+// without blocking, dropping the oldest queued event if dispatch can't keep up.
+type subscription struct {
+	out     chan Event
+	pending chan Event
+
+	mu     sync.Mutex
+	closed bool
+
+	filter       EventFilter
+	policy       SlowConsumerPolicy
+	blockTimeout time.Duration
+}
+
+// close asks this subscription's dispatch goroutine to finish delivering whatever
+// is already queued and then close out. Safe to call more than once.
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.pending)
+	}
+}
+
+// EventBus is an in-process publish/subscribe hub modeled after tendermint's
+// libs/pubsub EventBus: every subscriber is named, gets its own buffered channel
+// and dispatch goroutine, and can pick a filter plus a slow-consumer policy so one
+// laggard can't stall Publish or any other subscriber.
+type EventBus struct {
+	mu   sync.RWMutex
+	subs map[string]*subscription
+}
+
+// NewEventBus creates an empty EventBus ready to use.
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[string]*subscription)}
+}
+
+// Subscribe registers a new subscriber under name with the given buffer size and no
+// filter, using the default slow-consumer policy (PolicyDropOldest). The returned
+// channel is closed once the subscriber is unsubscribed, ctx is done, or the bus is
+// closed.
+func (b *EventBus) Subscribe(ctx context.Context, name string, buffer int) (<-chan Event, error) {
+	return b.SubscribeWithOptions(ctx, name, buffer, SubscribeOptions{})
+}
+
+// SubscribeWithOptions is like Subscribe but additionally allows configuring a filter
+// and a slow-consumer policy for the subscriber.
+func (b *EventBus) SubscribeWithOptions(ctx context.Context, name string, buffer int, opts SubscribeOptions) (<-chan Event, error) {
+	b.mu.Lock()
+	if _, taken := b.subs[name]; taken {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionExists, name)
+	}
+	sub := &subscription{
+		out:          make(chan Event, buffer),
+		pending:      make(chan Event, subscriptionPendingBuffer),
+		filter:       opts.Filter,
+		policy:       opts.Policy,
+		blockTimeout: opts.BlockTimeout,
+	}
+	b.subs[name] = sub
+	b.mu.Unlock()
+
+	go b.dispatch(name, sub)
+
+	if ctx != nil {
+		go func() {
+			<-ctx.Done()
+			b.Unsubscribe(name)
+		}()
+	}
+
+	return sub.out, nil
+}
+
+// Unsubscribe removes name's subscription, if any, and closes its channel.
+func (b *EventBus) Unsubscribe(name string) {
+	b.mu.Lock()
+	sub, ok := b.subs[name]
+	if ok {
+		delete(b.subs, name)
+	}
+	b.mu.Unlock()
+
+	if ok {
+		sub.close()
+	}
+}
+
+// Publish fans ev out to every subscriber whose filter accepts it. Handing ev to a
+// subscriber never blocks Publish: each subscriber's slow-consumer policy is applied
+// by its own dispatch goroutine, not here.
+func (b *EventBus) Publish(ev Event) {
+	b.mu.RLock()
+	subs := make([]*subscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.filter != nil && !sub.filter(ev) {
+			continue
+		}
+		b.enqueue(sub, ev)
+	}
+}
+
+// enqueue hands ev to sub's dispatch goroutine without blocking the publisher: if
+// pending is itself full — e.g. dispatch is stuck waiting out a PolicyBlockWithTimeout
+// subscriber — the oldest queued event is dropped to make room. A subscriber's own
+// policy only governs what happens once an event reaches the front of its queue, not
+// how fast Publish can hand events off.
+func (b *EventBus) enqueue(sub *subscription, ev Event) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return
+	}
+
+	select {
+	case sub.pending <- ev:
+		return
+	default:
+	}
+
+	select {
+	case <-sub.pending:
+	default:
+	}
+	select {
+	case sub.pending <- ev:
+	default:
+	}
+}
+
+// dispatch is a subscription's sole writer to (and closer of) out: it drains
+// pending in order, applying sub's slow-consumer policy to whichever event is at
+// the front of the queue, until pending is closed or the subscriber disconnects.
+func (b *EventBus) dispatch(name string, sub *subscription) {
+	defer close(sub.out)
+	for ev := range sub.pending {
+		if b.deliver(name, sub, ev) {
+			return
+		}
+	}
+}
+
+// deliver tries to hand ev to sub.out, applying sub's slow-consumer policy if it's
+// currently full. Reports whether the subscriber disconnected as a result.
+func (b *EventBus) deliver(name string, sub *subscription, ev Event) (disconnected bool) {
+	select {
+	case sub.out <- ev:
+		return false
+	default:
+	}
+
+	switch sub.policy {
+	case PolicyBlockWithTimeout:
+		timeout := sub.blockTimeout
+		if timeout <= 0 {
+			timeout = time.Second
+		}
+		select {
+		case sub.out <- ev:
+		case <-time.After(timeout):
+		}
+	case PolicyDisconnect:
+		b.Unsubscribe(name)
+		return true
+	default: // PolicyDropOldest
+		select {
+		case <-sub.out:
+		default:
+		}
+		select {
+		case sub.out <- ev:
+		default:
+		}
+	}
+	return false
+}
+
+// Close unsubscribes every current subscriber.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[string]*subscription)
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+}
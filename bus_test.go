@@ -0,0 +1,238 @@
+package chatvotes
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventBus_PublishSubscribe(t *testing.T) {
+	t.Run("delivers published events to a subscriber", func(t *testing.T) {
+		bus := NewEventBus()
+		events, err := bus.Subscribe(context.Background(), "sub", 1)
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		bus.Publish(VotingStartedEvent{})
+
+		select {
+		case ev := <-events:
+			if _, ok := ev.(VotingStartedEvent); !ok {
+				t.Fatalf("expected VotingStartedEvent, got %T", ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	})
+
+	t.Run("rejects a second subscription with the same name", func(t *testing.T) {
+		bus := NewEventBus()
+		if _, err := bus.Subscribe(context.Background(), "sub", 1); err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+		if _, err := bus.Subscribe(context.Background(), "sub", 1); err == nil {
+			t.Fatal("expected second subscription with the same name to fail")
+		}
+	})
+
+	t.Run("filter restricts which events reach a subscriber", func(t *testing.T) {
+		bus := NewEventBus()
+		events, err := bus.SubscribeWithOptions(context.Background(), "sub", 2, SubscribeOptions{
+			Filter: func(ev Event) bool {
+				_, ok := ev.(VotingFinishedEvent)
+				return ok
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		bus.Publish(VotingStartedEvent{})
+		bus.Publish(VotingFinishedEvent{Voting: Voting{voteCount: 3}})
+
+		select {
+		case ev := <-events:
+			finished, ok := ev.(VotingFinishedEvent)
+			if !ok {
+				t.Fatalf("expected VotingFinishedEvent, got %T", ev)
+			}
+			if finished.Voting.VoteCount() != 3 {
+				t.Errorf("expected vote count 3, got %d", finished.Voting.VoteCount())
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+
+		select {
+		case ev := <-events:
+			t.Fatalf("expected no further events, got %T", ev)
+		default:
+		}
+	})
+
+	t.Run("unsubscribe closes the subscriber channel", func(t *testing.T) {
+		bus := NewEventBus()
+		events, err := bus.Subscribe(context.Background(), "sub", 1)
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		bus.Unsubscribe("sub")
+
+		select {
+		case _, ok := <-events:
+			if ok {
+				t.Fatal("expected channel to be closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for channel to close")
+		}
+	})
+
+	t.Run("drop-oldest policy keeps the newest event when the buffer is full", func(t *testing.T) {
+		bus := NewEventBus()
+		events, err := bus.SubscribeWithOptions(context.Background(), "sub", 1, SubscribeOptions{
+			Policy: PolicyDropOldest,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		bus.Publish(VoteAcceptedEvent{Vote: &Vote{choice: 1}})
+		bus.Publish(VoteAcceptedEvent{Vote: &Vote{choice: 2}})
+
+		// Give sub's dispatch goroutine a moment to drain both queued events
+		// and apply drop-oldest at the out-channel level before we read, so
+		// this doesn't race a read against dispatch still processing the
+		// first event.
+		time.Sleep(20 * time.Millisecond)
+
+		select {
+		case ev := <-events:
+			accepted, ok := ev.(VoteAcceptedEvent)
+			if !ok {
+				t.Fatalf("expected VoteAcceptedEvent, got %T", ev)
+			}
+			if accepted.Vote.choice != 2 {
+				t.Errorf("expected the newest event to survive, got choice %d", accepted.Vote.choice)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	})
+
+	t.Run("a PolicyBlockWithTimeout subscriber stalled on its own backlog does not delay delivery to others", func(t *testing.T) {
+		bus := NewEventBus()
+		stalled, err := bus.SubscribeWithOptions(context.Background(), "stalled", 1, SubscribeOptions{
+			Policy:       PolicyBlockWithTimeout,
+			BlockTimeout: 200 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+		healthy, err := bus.SubscribeWithOptions(context.Background(), "healthy", 4, SubscribeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		// "stalled" is never drained: the first publish fills its one-slot
+		// buffer, so its dispatch goroutine is stuck waiting out its 200ms
+		// BlockTimeout from the second publish onward.
+		bus.Publish(VoteAcceptedEvent{Vote: &Vote{choice: 1}})
+		bus.Publish(VoteAcceptedEvent{Vote: &Vote{choice: 2}})
+
+		start := time.Now()
+		bus.Publish(VotingFinishedEvent{Voting: Voting{voteCount: 7}})
+
+		// healthy buffers all three events (it has ample capacity and nothing
+		// has read from it yet), so drain the two VoteAcceptedEvents ahead of
+		// the VotingFinishedEvent we actually care about.
+		for i := 0; i < 2; i++ {
+			select {
+			case <-healthy:
+			case <-time.After(50 * time.Millisecond):
+				t.Fatal("healthy subscriber didn't receive its earlier events promptly")
+			}
+		}
+		select {
+		case ev := <-healthy:
+			if finished, ok := ev.(VotingFinishedEvent); !ok || finished.Voting.VoteCount() != 7 {
+				t.Fatalf("expected VotingFinishedEvent with vote count 7, got %#v", ev)
+			}
+		case <-time.After(50 * time.Millisecond):
+			t.Fatal("healthy subscriber didn't receive its event promptly")
+		}
+		if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+			t.Errorf("Publish took %v, expected it not to wait out the stalled subscriber's BlockTimeout", elapsed)
+		}
+
+		_ = stalled // intentionally never drained
+	})
+
+	t.Run("disconnect policy unsubscribes once the buffer is full", func(t *testing.T) {
+		bus := NewEventBus()
+		events, err := bus.SubscribeWithOptions(context.Background(), "sub", 1, SubscribeOptions{
+			Policy: PolicyDisconnect,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		bus.Publish(VoteAcceptedEvent{Vote: &Vote{choice: 1}})
+		bus.Publish(VoteAcceptedEvent{Vote: &Vote{choice: 2}})
+
+		// Give sub's dispatch goroutine a moment to deliver the first event and
+		// then disconnect on the second, so reading below doesn't race dispatch
+		// still processing its queue.
+		time.Sleep(20 * time.Millisecond)
+
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatal("expected the first queued event to be delivered before disconnecting")
+			}
+			if accepted, ok2 := ev.(VoteAcceptedEvent); !ok2 || accepted.Vote.choice != 1 {
+				t.Fatalf("expected the first queued vote to survive, got %#v", ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the first event")
+		}
+
+		select {
+		case _, ok := <-events:
+			if ok {
+				t.Fatal("expected channel to be closed after disconnect")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for channel to close after disconnect")
+		}
+
+		bus.mu.Lock()
+		_, stillSubscribed := bus.subs["sub"]
+		bus.mu.Unlock()
+		if stillSubscribed {
+			t.Error("expected the subscriber to have been removed from the bus")
+		}
+	})
+
+	t.Run("cancelling the subscribe context unsubscribes", func(t *testing.T) {
+		bus := NewEventBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := bus.Subscribe(ctx, "sub", 1)
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		cancel()
+
+		select {
+		case _, ok := <-events:
+			if ok {
+				t.Fatal("expected channel to be closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for channel to close")
+		}
+	})
+}
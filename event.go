@@ -0,0 +1,39 @@
+package chatvotes
+
+// Event is implemented by every value published on a PollSite's event bus.
+// Consumers type-switch on the concrete type to find out what happened.
+type Event interface {
+	isEvent()
+}
+
+// StateTransitionEvent is published whenever a PollSite moves between states.
+type StateTransitionEvent struct {
+	StateTransition
+}
+
+// VoteAcceptedEvent is published when a vote is recorded in the VoteStore.
+type VoteAcceptedEvent struct {
+	Vote *Vote
+}
+
+// VoteRejectedEvent is published when a vote is dropped instead of being recorded,
+// e.g. because the voter has already voted in the current voting.
+type VoteRejectedEvent struct {
+	Vote   *Vote
+	Reason string
+}
+
+// VotingStartedEvent is published the moment a voting starts, i.e. right before the
+// poll site transitions from StateIdle to StateActiveVoting.
+type VotingStartedEvent struct{}
+
+// VotingFinishedEvent is published once a voting is released and its result is final.
+type VotingFinishedEvent struct {
+	Voting Voting
+}
+
+func (StateTransitionEvent) isEvent() {}
+func (VoteAcceptedEvent) isEvent()    {}
+func (VoteRejectedEvent) isEvent()    {}
+func (VotingStartedEvent) isEvent()   {}
+func (VotingFinishedEvent) isEvent()  {}
@@ -3,6 +3,7 @@ package chatvotes
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 )
 
@@ -47,24 +48,67 @@ type StateTransition struct {
 
 // PollSiteConfig is the configuration for a poll site.
 type PollSiteConfig struct {
-	// startTimeout is the maximum duration in which the required vote count
-	// specified in startThreshold has to be reached.
-	startTimeout time.Duration
+	// StartTimeout is the maximum duration in which the required vote count
+	// specified in StartThreshold has to be reached.
+	StartTimeout time.Duration
 
-	// startThreshold is the minimum number of votes that to has to be reached
+	// StartThreshold is the minimum number of votes that to has to be reached
 	// before the start times out.
-	startThreshold int
+	StartThreshold int
 
-	// releaseTimeout is the maximum duration in which votes have to be registered
+	// ReleaseTimeout is the maximum duration in which votes have to be registered
 	// to reset the release timeout and keep the voting alive.
 	// After reaching the timeout, the poll site will transition back to StateIdle.
-	releaseTimeout time.Duration
+	// It may be left zero if ReleaseStrategy is set to a strategy that doesn't
+	// need it (e.g. RateReleaseStrategy); the poll loop then falls back to
+	// defaultReleasePollInterval for how often it consults the strategy.
+	ReleaseTimeout time.Duration
+
+	// VoteWeigher, if set, assigns a weight to each vote, e.g. so moderators or
+	// subscribers count for more than one regular viewer. It is only consulted if
+	// StartStrategy is nil, in which case it selects a WeightedSumStartStrategy
+	// using StartThreshold as the weighted sum to reach.
+	VoteWeigher VoteWeigher
+
+	// StartStrategy decides when enough votes have accumulated to start a voting.
+	// Defaults to CountStartStrategy{Threshold: StartThreshold} (or, if VoteWeigher
+	// is set, WeightedSumStartStrategy) if nil.
+	StartStrategy StartStrategy
+
+	// ReleaseStrategy decides when an active voting should be finalized.
+	// Defaults to a timeout-based strategy using ReleaseTimeout if nil.
+	ReleaseStrategy ReleaseStrategy
 }
 
 // NewPollSite creates and sets up a new PollSite.
 func NewPollSite(store VoteStore, p *PollSiteConfig) *PollSite {
-	return &PollSite{
+	return newPollSite(store, p, nil)
+}
+
+// NewPollSiteWithWAL is like NewPollSite but additionally records every
+// state-affecting event to wal. On the first Start, the poll site replays wal
+// before entering its main loop to reconstruct state, voteCache, and any
+// in-progress voting, so it can resume exactly where it left off after a crash.
+func NewPollSiteWithWAL(store VoteStore, p *PollSiteConfig, wal WAL) *PollSite {
+	return newPollSite(store, p, wal)
+}
+
+func newPollSite(store VoteStore, p *PollSiteConfig, wal WAL) *PollSite {
+	if p.StartStrategy == nil {
+		if p.VoteWeigher != nil {
+			p.StartStrategy = WeightedSumStartStrategy{Weigher: p.VoteWeigher, Threshold: float64(p.StartThreshold)}
+		} else {
+			p.StartStrategy = CountStartStrategy{Threshold: p.StartThreshold}
+		}
+	}
+	if p.ReleaseStrategy == nil {
+		p.ReleaseStrategy = NewTimeoutReleaseStrategy(p.ReleaseTimeout)
+	}
+
+	ps := &PollSite{
 		store:           store,
+		bus:             NewEventBus(),
+		wal:             wal,
 		stateChanged:    make(chan StateTransition),
 		state:           StateStopped,
 		incomingVotes:   make(chan *Vote, 5),
@@ -72,6 +116,8 @@ func NewPollSite(store VoteStore, p *PollSiteConfig) *PollSite {
 		finishedVotings: make(chan Voting),
 		config:          p,
 	}
+	ps.wireLegacyShims()
+	return ps
 }
 
 // PollSite is a manager that processes incoming votes and decides based on PollSiteConfig
@@ -81,20 +127,66 @@ type PollSite struct {
 	incomingVotes chan *Vote
 	done          chan struct{}
 	config        *PollSiteConfig
-	voteCache     []*Vote
+
+	bus         *EventBus
+	wal         WAL
+	walReplayed bool
 
 	stateChanged chan StateTransition
-	state        PollSiteState
+
+	// mu guards state and voteCache: both are written from within the Start
+	// loop's goroutine (and state also by Stop, from its caller's goroutine),
+	// and read from other goroutines via State and PendingVotes (e.g. by
+	// Router's eviction and startup checks).
+	mu        sync.Mutex
+	state     PollSiteState
+	voteCache []*Vote
 
 	startTicker     *time.Ticker
 	releaseTicker   *time.Ticker
 	finishedVotings chan Voting
 }
 
+// these names back the legacy StateChanged/VotingFinished shims and are only ever
+// used on a PollSite's own, private EventBus, so collisions are impossible.
+const (
+	legacyStateSubscriber  = "pollsite.legacy.state"
+	legacyVotingSubscriber = "pollsite.legacy.voting"
+)
+
+// wireLegacyShims subscribes internally on the bus and forwards the events of
+// interest onto stateChanged/finishedVotings with the same non-blocking-send
+// semantics StateChanged/VotingFinished always had, so existing callers keep working.
+func (ps *PollSite) wireLegacyShims() {
+	stateEvents, _ := ps.bus.Subscribe(context.Background(), legacyStateSubscriber, 1)
+	votingEvents, _ := ps.bus.Subscribe(context.Background(), legacyVotingSubscriber, 1)
+
+	go func() {
+		for ev := range stateEvents {
+			if e, ok := ev.(StateTransitionEvent); ok {
+				select {
+				case ps.stateChanged <- e.StateTransition:
+				default:
+				}
+			}
+		}
+	}()
+	go func() {
+		for ev := range votingEvents {
+			if e, ok := ev.(VotingFinishedEvent); ok {
+				select {
+				case ps.finishedVotings <- e.Voting:
+				default:
+				}
+			}
+		}
+	}()
+}
+
 // InsertVote tries to insert a vote.
 // Returns ErrNotStarted if the poll site has not yet been started.
 func (ps *PollSite) InsertVote(vote *Vote) error {
-	if ps.state == StateStopped {
+	if ps.State() == StateStopped {
 		return ErrNotStarted
 	}
 	ps.incomingVotes <- vote
@@ -105,16 +197,28 @@ func (ps *PollSite) InsertVote(vote *Vote) error {
 func (ps *PollSite) Stop() {
 	ps.setNextState(StateStopped)
 	close(ps.done)
+	ps.bus.Close()
 }
 
 // Start starts the poll site blocking until Stop is called
 // or the context is cancelled.
 // Returns an error on context cancellation explaining why.
 func (ps *PollSite) Start(ctx context.Context) error {
-	ps.setNextState(StateIdle)
+	if ps.wal != nil && !ps.walReplayed {
+		if err := ps.replayWAL(); err != nil {
+			return err
+		}
+		ps.walReplayed = true
+	}
+
+	// A fresh poll site, or one that recovered nothing from its WAL, always starts
+	// in StateIdle. One recovered mid-voting keeps the state replayWAL reconstructed.
+	if ps.State() == StateStopped {
+		ps.setNextState(StateIdle)
+	}
 
-	ps.startTicker = time.NewTicker(ps.config.startTimeout)
-	ps.releaseTicker = time.NewTicker(ps.config.releaseTimeout)
+	ps.startTicker = time.NewTicker(ps.config.StartTimeout)
+	ps.releaseTicker = time.NewTicker(ps.releasePollInterval())
 
 	for {
 		select {
@@ -132,71 +236,225 @@ func (ps *PollSite) Start(ctx context.Context) error {
 	}
 }
 
+// defaultReleasePollInterval is the release ticker's cadence when ReleaseTimeout
+// is zero, i.e. when a caller supplied a custom ReleaseStrategy that doesn't rely
+// on ReleaseTimeout at all.
+const defaultReleasePollInterval = 10 * time.Millisecond
+
+// releasePollInterval is how often the poll loop asks config.ReleaseStrategy
+// whether it's time to release, decoupled from ReleaseTimeout so a custom
+// strategy (e.g. RateReleaseStrategy) can be used with ReleaseTimeout left zero.
+func (ps *PollSite) releasePollInterval() time.Duration {
+	if ps.config.ReleaseTimeout > 0 {
+		return ps.config.ReleaseTimeout
+	}
+	return defaultReleasePollInterval
+}
+
 // StateChanged publishes all stage changes.
 // This is useful to determine if a voting has started.
+//
+// Deprecated: subscribers that care about back-pressure, filtering, or more event
+// types than a plain state transition should use Subscribe instead.
 func (ps *PollSite) StateChanged() <-chan StateTransition {
 	return ps.stateChanged
 }
 
 // VotingFinished publishes all finished votings.
+//
+// Deprecated: subscribers that care about back-pressure, filtering, or more event
+// types than a finished voting should use Subscribe instead.
 func (ps *PollSite) VotingFinished() <-chan Voting {
 	return ps.finishedVotings
 }
 
+// Subscribe registers a new named subscriber on the poll site's event bus and returns
+// a channel of every Event published on it, buffered to buffer entries. The
+// subscription is torn down, and the channel closed, once ctx is done or Unsubscribe
+// is called with the same name.
+func (ps *PollSite) Subscribe(ctx context.Context, name string, buffer int) (<-chan Event, error) {
+	return ps.bus.Subscribe(ctx, name, buffer)
+}
+
+// SubscribeWithOptions is like Subscribe but additionally allows configuring an
+// EventFilter and a SlowConsumerPolicy for the subscriber.
+func (ps *PollSite) SubscribeWithOptions(ctx context.Context, name string, buffer int, opts SubscribeOptions) (<-chan Event, error) {
+	return ps.bus.SubscribeWithOptions(ctx, name, buffer, opts)
+}
+
+// Unsubscribe removes the named subscriber, if any, from the poll site's event bus.
+func (ps *PollSite) Unsubscribe(name string) {
+	ps.bus.Unsubscribe(name)
+}
+
+// State returns the poll site's current state.
+func (ps *PollSite) State() PollSiteState {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.state
+}
+
+// PendingVotes returns the number of votes accumulated in the idle vote cache,
+// i.e. votes received in StateIdle that haven't yet started a voting and that
+// neither the start timeout nor StartStrategy has discarded.
+func (ps *PollSite) PendingVotes() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return len(ps.voteCache)
+}
+
+// replayWAL reconstructs state, voteCache, and the VoteStore from ps.wal, then
+// truncates it up to the last finalized voting if it supports that.
+func (ps *PollSite) replayWAL() error {
+	state := StateStopped
+	var cache []*Vote
+	var lastFinishedSeq uint64
+
+	err := ps.wal.Replay(func(entry WALEntry) error {
+		switch entry.Type {
+		case WALEntryStateTransition:
+			state = entry.Transition.To
+			if state == StateActiveVoting {
+				cache = nil
+			}
+		case WALEntryVoteCached:
+			// Drive replay through the configured StartStrategy rather than
+			// just appending, so a strategy that evicts cache entries (e.g.
+			// RollingWindowStartStrategy) ends up with the same keep list it
+			// would have live. start is ignored: if this vote had reached the
+			// threshold, the WALEntryStateTransition that follows it already
+			// clears cache.
+			if ta, ok := ps.config.StartStrategy.(TimeAwareStartStrategy); ok {
+				_, cache = ta.OnVoteAt(entry.Vote, cache, entry.Time)
+			} else {
+				_, cache = ps.config.StartStrategy.OnVote(entry.Vote, cache)
+			}
+		case WALEntryCacheReset:
+			cache = nil
+		case WALEntryVoteAccepted:
+			ps.store.AddUniqueVote(entry.Vote)
+		case WALEntryVotingFinished:
+			ps.store.Reset()
+			cache = nil
+			lastFinishedSeq = entry.Seq
+		case WALEntryReleaseTimeout:
+			// Informational only: the WALEntryVotingFinished and
+			// WALEntryStateTransition entries that follow carry everything
+			// needed to reconstruct state.
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	ps.state = state
+	ps.voteCache = cache
+	ps.mu.Unlock()
+
+	if lastFinishedSeq == 0 {
+		return nil
+	}
+	if truncator, ok := ps.wal.(Truncator); ok {
+		return truncator.TruncateBefore(lastFinishedSeq)
+	}
+	return nil
+}
+
+// appendWAL writes entry to ps.wal, if one is configured. A write failure is not
+// fatal to vote processing, but means that entry won't be recoverable after a crash.
+func (ps *PollSite) appendWAL(entry WALEntry) {
+	if ps.wal == nil {
+		return
+	}
+	_ = ps.wal.Append(entry)
+}
+
 func (ps *PollSite) handleStartTimeout() {
-	if ps.state != StateIdle {
+	if ps.State() != StateIdle {
 		return
 	}
+	ps.appendWAL(WALEntry{Type: WALEntryCacheReset})
+	ps.mu.Lock()
 	ps.voteCache = nil
+	ps.mu.Unlock()
 }
 
 func (ps *PollSite) handleNewVote(vote *Vote) {
-	switch ps.state {
+	switch ps.State() {
 	case StateIdle:
-		ps.voteCache = append(ps.voteCache, vote)
-		if len(ps.voteCache) < ps.config.startThreshold {
+		// Persisted as cached regardless of what StartStrategy does with it below,
+		// so a crash can still recover it as a pending vote. Time is recorded so a
+		// TimeAwareStartStrategy can replay this vote at its real arrival time
+		// rather than whenever replay happens to run.
+		ps.appendWAL(WALEntry{Type: WALEntryVoteCached, Vote: vote, Time: time.Now()})
+
+		ps.mu.Lock()
+		start, keep := ps.config.StartStrategy.OnVote(vote, ps.voteCache)
+		ps.voteCache = keep
+		ps.mu.Unlock()
+		if !start {
 			return
 		}
 
-		for _, v := range ps.voteCache {
-			ps.store.AddUniqueVote(v)
-		}
+		ps.mu.Lock()
+		toCommit := ps.voteCache
 		ps.voteCache = nil
-		ps.releaseTicker.Reset(ps.config.releaseTimeout)
+		ps.mu.Unlock()
+
+		for _, v := range toCommit {
+			ps.recordVote(v)
+			ps.config.ReleaseStrategy.OnVote(v)
+		}
+		ps.bus.Publish(VotingStartedEvent{})
 		ps.setNextState(StateActiveVoting)
 	case StateActiveVoting:
-		ps.store.AddUniqueVote(vote)
+		ps.recordVote(vote)
+		ps.config.ReleaseStrategy.OnVote(vote)
+	}
+}
 
-		ps.releaseTicker.Reset(ps.config.releaseTimeout)
+// recordVote adds vote to the store and publishes whether it was accepted or
+// rejected as a duplicate.
+func (ps *PollSite) recordVote(vote *Vote) {
+	if ps.store.AddUniqueVote(vote) {
+		ps.appendWAL(WALEntry{Type: WALEntryVoteAccepted, Vote: vote})
+		ps.bus.Publish(VoteAcceptedEvent{Vote: vote})
+	} else {
+		ps.bus.Publish(VoteRejectedEvent{Vote: vote, Reason: "voter has already voted"})
 	}
 }
 
 func (ps *PollSite) handleReleaseTimeout() {
-	if ps.state != StateActiveVoting {
+	if ps.State() != StateActiveVoting {
+		return
+	}
+	if !ps.config.ReleaseStrategy.ShouldRelease(time.Now()) {
 		return
 	}
 
-	select {
-	case ps.finishedVotings <- Voting{
+	ps.appendWAL(WALEntry{Type: WALEntryReleaseTimeout})
+
+	voting := Voting{
 		voteCount: ps.store.GetVoteCount(),
 		votes:     ps.store.GetVotes(),
-	}:
-	default:
 	}
+	ps.appendWAL(WALEntry{Type: WALEntryVotingFinished, Voting: &voting})
+	ps.bus.Publish(VotingFinishedEvent{Voting: voting})
 
 	ps.store.Reset()
-	ps.startTicker.Reset(ps.config.releaseTimeout)
+	ps.startTicker.Reset(ps.releasePollInterval())
 	ps.setNextState(StateIdle)
 }
 
 func (ps *PollSite) setNextState(state PollSiteState) {
+	ps.mu.Lock()
 	fromState := ps.state
 	ps.state = state
-	select {
-	case ps.stateChanged <- StateTransition{
-		From: fromState,
-		To:   state,
-	}:
-	default:
-	}
+	ps.mu.Unlock()
+
+	transition := StateTransition{From: fromState, To: state}
+	ps.appendWAL(WALEntry{Type: WALEntryStateTransition, Transition: &transition})
+	ps.bus.Publish(StateTransitionEvent{transition})
 }
@@ -3,6 +3,7 @@ package chatvotes
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -307,6 +308,191 @@ func TestPollSiteVotingFinished(t *testing.T) {
 	})
 }
 
+func TestPollSite_CrashRecovery(t *testing.T) {
+	t.Run("resumes an in-progress voting after a crash and produces the same result", func(t *testing.T) {
+		walPath := filepath.Join(t.TempDir(), "pollsite.wal")
+		cfg := &PollSiteConfig{
+			StartThreshold: 5,
+			// Well above the test's runtime so it doesn't interfere with the
+			// in-progress voting this test simulates a crash during.
+			StartTimeout:   time.Second,
+			ReleaseTimeout: time.Millisecond * 20,
+		}
+
+		store := newStubVoteStore()
+		wal, err := NewFileWAL(walPath)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal: %v", err)
+		}
+		site := NewPollSiteWithWAL(store, cfg, wal)
+
+		// No defer site.Stop(): we crash it instead of shutting it down cleanly.
+		go site.Start(context.Background())
+		time.Sleep(time.Millisecond)
+
+		for i := 0; i < 4; i++ {
+			site.InsertVote(&Vote{voterID: fmt.Sprintf("voter %d", i), choice: 1})
+		}
+		time.Sleep(5 * time.Millisecond)
+		if err := wal.Close(); err != nil {
+			t.Fatalf("unexpected error closing wal: %v", err)
+		}
+
+		// Resume: a new PollSite and WAL over the same file recovers the 4 cached
+		// votes without having started a voting yet.
+		recoveredStore := newStubVoteStore()
+		recoveredWAL, err := NewFileWAL(walPath)
+		if err != nil {
+			t.Fatalf("unexpected error reopening wal: %v", err)
+		}
+		recoveredSite := NewPollSiteWithWAL(recoveredStore, cfg, recoveredWAL)
+		defer recoveredSite.Stop()
+
+		go recoveredSite.Start(context.Background())
+		time.Sleep(time.Millisecond)
+
+		if err := recoveredSite.InsertVote(&Vote{voterID: "voter 4", choice: 1}); err != nil {
+			t.Fatalf("unexpected error inserting vote on recovered site: %v", err)
+		}
+
+		assertNoTimeout(t, cfg.ReleaseTimeout*8, func() {
+			voting := <-recoveredSite.VotingFinished()
+			if voteCount := voting.VoteCount(); voteCount != 5 {
+				t.Errorf("expected vote count to be %d but got %d", 5, voteCount)
+			}
+			if result := voting.Result(); result[1] != 5 {
+				t.Errorf("expected all 5 recovered+new votes for choice 1, got %+v", result)
+			}
+		})
+	})
+
+	t.Run("a start-timeout cache reset before a crash is not resurrected on replay", func(t *testing.T) {
+		walPath := filepath.Join(t.TempDir(), "pollsite.wal")
+		cfg := &PollSiteConfig{
+			StartThreshold: 5,
+			StartTimeout:   time.Millisecond * 10,
+			ReleaseTimeout: time.Second,
+		}
+
+		store := newStubVoteStore()
+		wal, err := NewFileWAL(walPath)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal: %v", err)
+		}
+		site := NewPollSiteWithWAL(store, cfg, wal)
+
+		// No defer site.Stop(): we crash it instead of shutting it down cleanly.
+		go site.Start(context.Background())
+		time.Sleep(time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			site.InsertVote(&Vote{voterID: fmt.Sprintf("voter %d", i), choice: 1})
+		}
+		// Let the start timeout fire and clear the cache before crashing.
+		time.Sleep(cfg.StartTimeout * 3)
+		if err := wal.Close(); err != nil {
+			t.Fatalf("unexpected error closing wal: %v", err)
+		}
+
+		recoveredStore := newStubVoteStore()
+		recoveredWAL, err := NewFileWAL(walPath)
+		if err != nil {
+			t.Fatalf("unexpected error reopening wal: %v", err)
+		}
+		recoveredSite := NewPollSiteWithWAL(recoveredStore, cfg, recoveredWAL)
+		defer recoveredSite.Stop()
+
+		go recoveredSite.Start(context.Background())
+		time.Sleep(time.Millisecond)
+
+		if pending := recoveredSite.PendingVotes(); pending != 0 {
+			t.Errorf("expected the discarded cache to stay discarded after recovery, got %d pending votes", pending)
+		}
+	})
+
+	t.Run("a RollingWindowStartStrategy's evictions survive a crash", func(t *testing.T) {
+		walPath := filepath.Join(t.TempDir(), "pollsite.wal")
+		newCfg := func() *PollSiteConfig {
+			return &PollSiteConfig{
+				StartThreshold: 2,
+				StartTimeout:   time.Second,
+				ReleaseTimeout: time.Second,
+				StartStrategy:  NewRollingWindowStartStrategy(2, 20*time.Millisecond),
+			}
+		}
+
+		store := newStubVoteStore()
+		wal, err := NewFileWAL(walPath)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal: %v", err)
+		}
+		site := NewPollSiteWithWAL(store, newCfg(), wal)
+
+		// No defer site.Stop(): we crash it instead of shutting it down cleanly.
+		go site.Start(context.Background())
+		time.Sleep(time.Millisecond)
+
+		if err := site.InsertVote(&Vote{voterID: "v1", choice: 1}); err != nil {
+			t.Fatalf("unexpected error inserting v1: %v", err)
+		}
+		// Let v1 fall out of the 20ms rolling window before v2 arrives.
+		time.Sleep(40 * time.Millisecond)
+		if err := site.InsertVote(&Vote{voterID: "v2", choice: 1}); err != nil {
+			t.Fatalf("unexpected error inserting v2: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if pending := site.PendingVotes(); pending != 1 {
+			t.Fatalf("expected v1 to have fallen out of the window live, got %d pending votes", pending)
+		}
+		if err := wal.Close(); err != nil {
+			t.Fatalf("unexpected error closing wal: %v", err)
+		}
+
+		recoveredStore := newStubVoteStore()
+		recoveredWAL, err := NewFileWAL(walPath)
+		if err != nil {
+			t.Fatalf("unexpected error reopening wal: %v", err)
+		}
+		recoveredSite := NewPollSiteWithWAL(recoveredStore, newCfg(), recoveredWAL)
+		defer recoveredSite.Stop()
+
+		go recoveredSite.Start(context.Background())
+		time.Sleep(time.Millisecond)
+
+		if pending := recoveredSite.PendingVotes(); pending != 1 {
+			t.Errorf("expected replay to reconstruct the same 1 pending vote (v1 evicted) the live run had, got %d", pending)
+		}
+	})
+}
+
+func TestPollSite_ReleaseTimeoutOptionalWithCustomStrategy(t *testing.T) {
+	t.Run("a zero ReleaseTimeout does not panic when ReleaseStrategy is set", func(t *testing.T) {
+		store := newStubVoteStore()
+		site := NewPollSite(store, &PollSiteConfig{
+			StartThreshold: 2,
+			StartTimeout:   time.Second,
+			ReleaseStrategy: &RateReleaseStrategy{
+				MinVotesPerSecond: 1000,
+				Window:            10 * time.Millisecond,
+				Sustained:         5 * time.Millisecond,
+			},
+		})
+		defer site.Stop()
+
+		mustStartSilently(t, site)
+
+		go func() {
+			site.InsertVote(&Vote{voterID: "voter 0", choice: 1})
+			site.InsertVote(&Vote{voterID: "voter 1", choice: 1})
+		}()
+
+		assertNoTimeout(t, time.Second, func() {
+			<-site.VotingFinished()
+		})
+	})
+}
+
 func assertEmptyStubStore(t *testing.T, store *StubVoteStore) {
 	t.Helper()
 	if len(store.votes) != 0 {
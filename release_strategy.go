@@ -0,0 +1,115 @@
+package chatvotes
+
+import (
+	"sync"
+	"time"
+)
+
+// ReleaseStrategy decides when an active voting should be finalized. OnVote is
+// called for every vote recorded during the voting; ShouldRelease is polled
+// periodically and, once it returns true, the voting is finalized.
+type ReleaseStrategy interface {
+	OnVote(vote *Vote)
+	ShouldRelease(now time.Time) bool
+}
+
+var _ ReleaseStrategy = (*TimeoutReleaseStrategy)(nil)
+
+// TimeoutReleaseStrategy releases a voting once Timeout has passed without a new
+// vote. This is chatvotes' original, idle-timeout-only behavior.
+type TimeoutReleaseStrategy struct {
+	Timeout time.Duration
+	// Now returns the current time; defaults to time.Now. Exposed for tests.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// NewTimeoutReleaseStrategy creates a TimeoutReleaseStrategy with its deadline
+// already running, as if a vote had just been cast.
+func NewTimeoutReleaseStrategy(timeout time.Duration) *TimeoutReleaseStrategy {
+	s := &TimeoutReleaseStrategy{Timeout: timeout}
+	s.deadline = s.now().Add(timeout)
+	return s
+}
+
+func (s *TimeoutReleaseStrategy) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// OnVote implements ReleaseStrategy.
+func (s *TimeoutReleaseStrategy) OnVote(vote *Vote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadline = s.now().Add(s.Timeout)
+}
+
+// ShouldRelease implements ReleaseStrategy.
+func (s *TimeoutReleaseStrategy) ShouldRelease(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !now.Before(s.deadline)
+}
+
+var _ ReleaseStrategy = (*RateReleaseStrategy)(nil)
+
+// RateReleaseStrategy releases an active voting once the vote rate, measured over
+// the trailing Window, has stayed below MinVotesPerSecond for at least Sustained.
+type RateReleaseStrategy struct {
+	MinVotesPerSecond float64
+	Window            time.Duration
+	Sustained         time.Duration
+	// Now returns the current time; defaults to time.Now. Exposed for tests.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	voteTimes  []time.Time
+	belowSince time.Time
+	isBelow    bool
+}
+
+func (s *RateReleaseStrategy) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// OnVote implements ReleaseStrategy.
+func (s *RateReleaseStrategy) OnVote(vote *Vote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voteTimes = append(s.voteTimes, s.now())
+}
+
+// ShouldRelease implements ReleaseStrategy.
+func (s *RateReleaseStrategy) ShouldRelease(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-s.Window)
+	kept := s.voteTimes[:0]
+	for _, t := range s.voteTimes {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	s.voteTimes = kept
+
+	rate := float64(len(s.voteTimes)) / s.Window.Seconds()
+	if rate >= s.MinVotesPerSecond {
+		s.isBelow = false
+		return false
+	}
+
+	if !s.isBelow {
+		s.isBelow = true
+		s.belowSince = now
+		return false
+	}
+	return now.Sub(s.belowSince) >= s.Sustained
+}
@@ -0,0 +1,54 @@
+package chatvotes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeoutReleaseStrategy(t *testing.T) {
+	now := time.Now()
+	s := NewTimeoutReleaseStrategy(10 * time.Millisecond)
+	s.Now = func() time.Time { return now }
+	s.deadline = now.Add(10 * time.Millisecond)
+
+	if s.ShouldRelease(now.Add(5 * time.Millisecond)) {
+		t.Error("expected no release before the timeout elapses")
+	}
+
+	now = now.Add(5 * time.Millisecond)
+	s.OnVote(&Vote{voterID: "voter", choice: 1})
+	if s.ShouldRelease(now.Add(8 * time.Millisecond)) {
+		t.Error("expected a new vote to push the deadline back out")
+	}
+	if !s.ShouldRelease(now.Add(11 * time.Millisecond)) {
+		t.Error("expected a release once 10ms pass without a vote after OnVote reset the deadline")
+	}
+}
+
+func TestRateReleaseStrategy(t *testing.T) {
+	now := time.Now()
+	s := &RateReleaseStrategy{
+		MinVotesPerSecond: 2,
+		Window:            100 * time.Millisecond,
+		Sustained:         20 * time.Millisecond,
+		Now:               func() time.Time { return now },
+	}
+
+	for i := 0; i < 30; i++ {
+		s.OnVote(&Vote{voterID: "voter", choice: 1})
+	}
+	// 30 votes / 100ms window = 300/s, comfortably above the 2/s floor.
+	if s.ShouldRelease(now) {
+		t.Error("expected no release while the vote rate is high")
+	}
+
+	now = now.Add(150 * time.Millisecond)
+	if s.ShouldRelease(now) {
+		t.Error("expected the first below-floor check to only start the sustained timer")
+	}
+
+	now = now.Add(25 * time.Millisecond)
+	if !s.ShouldRelease(now) {
+		t.Error("expected a release once the rate has stayed below the floor for Sustained")
+	}
+}
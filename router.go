@@ -0,0 +1,263 @@
+package chatvotes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// RouterConfig configures a Router.
+type RouterConfig struct {
+	// EvictAfter is how long a topic's poll site may sit in StateIdle with an
+	// empty vote cache before the Router tears down its goroutine and closes its
+	// VoteStore (if the store implements io.Closer). The topic is re-spawned via
+	// factory on its next vote. Zero disables eviction.
+	EvictAfter time.Duration
+}
+
+// TopicEvent tags an Event with the topic of the PollSite that published it.
+type TopicEvent struct {
+	Topic string
+	Event Event
+}
+
+// Router fans incoming votes out to one PollSite per topic (e.g. "!a", "!b",
+// "#topic1"), spawning each lazily on its first vote via factory and multiplexing
+// every site's state transitions and finished votings onto a single
+// subscriber-facing channel tagged with topic.
+type Router struct {
+	factory func(topic string) (*PollSite, error)
+	config  RouterConfig
+
+	events chan TopicEvent
+
+	mu    sync.Mutex
+	sites map[string]*routedSite
+}
+
+// NewRouter creates a Router that lazily spawns one poll site per topic via
+// factory. Idle eviction is disabled; use NewRouterWithConfig to enable it.
+func NewRouter(factory func(topic string) (*PollSite, error)) *Router {
+	return NewRouterWithConfig(factory, RouterConfig{})
+}
+
+// NewRouterWithConfig is like NewRouter but additionally configures idle eviction.
+func NewRouterWithConfig(factory func(topic string) (*PollSite, error), cfg RouterConfig) *Router {
+	return &Router{
+		factory: factory,
+		config:  cfg,
+		events:  make(chan TopicEvent, 64),
+		sites:   make(map[string]*routedSite),
+	}
+}
+
+// routedSite bundles the resources a Router needs to later tear a topic's poll
+// site down: the context that stops its Start goroutine (and, via the same
+// cancellation, its Router subscription), and a channel closed once that
+// goroutine has actually returned.
+type routedSite struct {
+	site   *PollSite
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Events returns the channel every managed poll site's state transitions and
+// finished votings are multiplexed onto, tagged with topic. A slow receiver
+// drops events rather than blocking routing.
+func (r *Router) Events() <-chan TopicEvent {
+	return r.events
+}
+
+// Route delivers vote to topic's poll site, spawning it first via factory if this
+// is the topic's first vote (or if it was previously evicted or stopped).
+func (r *Router) Route(topic string, vote *Vote) error {
+	site, err := r.siteFor(topic)
+	if err != nil {
+		return err
+	}
+	return site.InsertVote(vote)
+}
+
+// Sites returns the topics that currently have a running poll site.
+func (r *Router) Sites() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	topics := make([]string, 0, len(r.sites))
+	for topic := range r.sites {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// Stop tears down topic's poll site, if one is running. The topic is re-spawned
+// via factory on its next vote.
+func (r *Router) Stop(topic string) {
+	r.mu.Lock()
+	rs, ok := r.sites[topic]
+	if ok {
+		delete(r.sites, topic)
+	}
+	r.mu.Unlock()
+
+	if ok {
+		rs.stop()
+	}
+}
+
+// StopAll tears down every poll site the Router currently manages.
+func (r *Router) StopAll() {
+	r.mu.Lock()
+	sites := r.sites
+	r.sites = make(map[string]*routedSite)
+	r.mu.Unlock()
+
+	for _, rs := range sites {
+		rs.stop()
+	}
+}
+
+// stop stops rs's poll site, waits for its Start goroutine to return, and then
+// closes its VoteStore if the store implements io.Closer.
+func (rs *routedSite) stop() {
+	rs.site.Stop()
+	rs.cancel()
+	<-rs.done
+	if closer, ok := rs.site.store.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// siteFor returns topic's poll site, spawning it via factory and its
+// event-forwarding and idle-eviction goroutines if it doesn't exist yet. The
+// spin-up wait happens after r.mu is released, so routing a vote to a brand-new
+// topic never holds up Route calls for other, already-running topics.
+func (r *Router) siteFor(topic string) (*PollSite, error) {
+	r.mu.Lock()
+	if rs, ok := r.sites[topic]; ok {
+		r.mu.Unlock()
+		return rs.site, nil
+	}
+
+	site, err := r.factory(topic)
+	if err != nil {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("router: spawning poll site for topic %q: %w", topic, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rs := &routedSite{site: site, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	r.sites[topic] = rs
+	r.mu.Unlock()
+
+	go func() {
+		defer close(rs.done)
+		site.Start(ctx)
+	}()
+	waitUntilStarted(site)
+	go r.forward(topic, rs)
+	if r.config.EvictAfter > 0 {
+		go r.evictWhenIdle(topic, rs)
+	}
+
+	return site, nil
+}
+
+// waitUntilStarted blocks until site's Start goroutine has progressed past
+// StateStopped, so callers can't race InsertVote against it. Start moves the
+// site to StateIdle (or further, for a replayed WAL) right after replaying its
+// WAL, if any, so this returns as soon as that replay is done.
+func waitUntilStarted(site *PollSite) {
+	for site.State() == StateStopped {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// routerSubscriber names the Router's own subscription on each managed poll
+// site's event bus. Every Router-managed site gets exactly one, so this never
+// collides with anything else subscribed on that site.
+const routerSubscriber = "chatvotes.router"
+
+// routerEventBuffer is how many events the Router lets build up on a managed
+// site's bus before its PolicyDropOldest default starts discarding the oldest
+// one; r.events applies its own drop policy on top of that at the router level.
+const routerEventBuffer = 32
+
+// isRouterEvent is the forward subscription's filter: Router only re-tags and
+// forwards state transitions and finished votings, mirroring the deprecated
+// StateChanged/VotingFinished shims it replaces.
+func isRouterEvent(ev Event) bool {
+	switch ev.(type) {
+	case StateTransitionEvent, VotingFinishedEvent:
+		return true
+	default:
+		return false
+	}
+}
+
+// forward multiplexes a single topic's state transitions and finished votings
+// onto r.events until its poll site is torn down. It subscribes on the site's
+// event bus rather than using the deprecated StateChanged/VotingFinished shims,
+// since those are single-slot, non-blocking-send channels that silently drop
+// events under exactly the sustained throughput a Router is meant to handle.
+func (r *Router) forward(topic string, rs *routedSite) {
+	events, err := rs.site.SubscribeWithOptions(rs.ctx, routerSubscriber, routerEventBuffer, SubscribeOptions{
+		Filter: isRouterEvent,
+	})
+	if err != nil {
+		return
+	}
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			r.publish(TopicEvent{Topic: topic, Event: ev})
+		case <-rs.done:
+			return
+		}
+	}
+}
+
+func (r *Router) publish(ev TopicEvent) {
+	select {
+	case r.events <- ev:
+	default:
+	}
+}
+
+// evictWhenIdle tears down rs once its poll site has spent EvictAfter continuously
+// in StateIdle with an empty vote cache.
+func (r *Router) evictWhenIdle(topic string, rs *routedSite) {
+	checkInterval := r.config.EvictAfter / 4
+	if checkInterval <= 0 {
+		checkInterval = time.Millisecond
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	var idleSince time.Time
+	for {
+		select {
+		case <-ticker.C:
+			if rs.site.State() != StateIdle || rs.site.PendingVotes() != 0 {
+				idleSince = time.Time{}
+				continue
+			}
+			if idleSince.IsZero() {
+				idleSince = time.Now()
+				continue
+			}
+			if time.Since(idleSince) >= r.config.EvictAfter {
+				r.Stop(topic)
+				return
+			}
+		case <-rs.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package chatvotes
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestPollSiteFactory() func(topic string) (*PollSite, error) {
+	return func(topic string) (*PollSite, error) {
+		return NewPollSite(newStubVoteStore(), &PollSiteConfig{
+			StartThreshold: 3,
+			StartTimeout:   time.Second,
+			ReleaseTimeout: time.Millisecond * 10,
+		}), nil
+	}
+}
+
+func TestRouter_Route(t *testing.T) {
+	t.Run("spawns a poll site per topic lazily and keeps them isolated", func(t *testing.T) {
+		router := NewRouter(newTestPollSiteFactory())
+		defer router.StopAll()
+
+		if err := router.Route("!a", &Vote{voterID: "v1", choice: 1}); err != nil {
+			t.Fatalf("unexpected error routing: %v", err)
+		}
+		if err := router.Route("!b", &Vote{voterID: "v1", choice: 1}); err != nil {
+			t.Fatalf("unexpected error routing: %v", err)
+		}
+
+		sites := router.Sites()
+		if len(sites) != 2 {
+			t.Fatalf("expected 2 active topics, got %d: %v", len(sites), sites)
+		}
+	})
+
+	t.Run("routing enough votes to a topic finishes a voting only for that topic", func(t *testing.T) {
+		router := NewRouter(newTestPollSiteFactory())
+		defer router.StopAll()
+
+		for i := 0; i < 3; i++ {
+			if err := router.Route("!a", &Vote{voterID: fmt.Sprintf("v%d", i), choice: 1}); err != nil {
+				t.Fatalf("unexpected error routing: %v", err)
+			}
+		}
+
+		var sawFinished bool
+		deadline := time.After(200 * time.Millisecond)
+		for !sawFinished {
+			select {
+			case ev := <-router.Events():
+				if finished, ok := ev.Event.(VotingFinishedEvent); ok {
+					if ev.Topic != "!a" {
+						t.Errorf("expected finished voting to be tagged with topic !a, got %q", ev.Topic)
+					}
+					if finished.Voting.VoteCount() != 3 {
+						t.Errorf("expected vote count 3, got %d", finished.Voting.VoteCount())
+					}
+					sawFinished = true
+				}
+			case <-deadline:
+				t.Fatal("timed out waiting for a finished voting event")
+			}
+		}
+	})
+
+	t.Run("stop removes a topic, a later vote re-spawns it", func(t *testing.T) {
+		router := NewRouter(newTestPollSiteFactory())
+		defer router.StopAll()
+
+		if err := router.Route("!a", &Vote{voterID: "v1", choice: 1}); err != nil {
+			t.Fatalf("unexpected error routing: %v", err)
+		}
+		router.Stop("!a")
+		if sites := router.Sites(); len(sites) != 0 {
+			t.Fatalf("expected no active topics after Stop, got %v", sites)
+		}
+
+		if err := router.Route("!a", &Vote{voterID: "v1", choice: 1}); err != nil {
+			t.Fatalf("unexpected error re-routing after stop: %v", err)
+		}
+		if sites := router.Sites(); len(sites) != 1 {
+			t.Fatalf("expected topic to be re-spawned, got %v", sites)
+		}
+	})
+}
+
+func TestRouter_NoDroppedFinishedEvents(t *testing.T) {
+	t.Run("every finished voting reaches Events, even back-to-back", func(t *testing.T) {
+		const cycles = 50
+		router := NewRouterWithConfig(newTestPollSiteFactory(), RouterConfig{})
+		defer router.StopAll()
+
+		deadline := time.After(5 * time.Second)
+		for c := 0; c < cycles; c++ {
+			for i := 0; i < 3; i++ {
+				if err := router.Route("!a", &Vote{voterID: fmt.Sprintf("c%d-v%d", c, i), choice: 1}); err != nil {
+					t.Fatalf("unexpected error routing: %v", err)
+				}
+			}
+
+			// Wait for this cycle's voting to finish before starting the next,
+			// so a dropped event surfaces as a timeout rather than silently
+			// letting two cycles' votes merge into one voting.
+			var finished bool
+			for !finished {
+				select {
+				case ev := <-router.Events():
+					if _, ok := ev.Event.(VotingFinishedEvent); ok {
+						finished = true
+					}
+				case <-deadline:
+					t.Fatalf("timed out waiting for cycle %d's finished-voting event", c)
+				}
+			}
+		}
+	})
+}
+
+// slowStartWAL delays its first Replay call by delay, simulating a poll site whose
+// spin-up (e.g. a large WAL to recover) takes a while.
+type slowStartWAL struct {
+	*MemoryWAL
+	delay time.Duration
+}
+
+func (w *slowStartWAL) Replay(fn func(entry WALEntry) error) error {
+	time.Sleep(w.delay)
+	return w.MemoryWAL.Replay(fn)
+}
+
+func TestRouter_ColdStartDoesNotBlockOtherTopics(t *testing.T) {
+	t.Run("routing to a slow-to-spin-up topic doesn't hold up routing to another", func(t *testing.T) {
+		const spinUpDelay = 100 * time.Millisecond
+
+		// Only "!slow" gets the delayed WAL; "!fast" spins up off a plain
+		// MemoryWAL so the test can tell "blocked on the router's lock" apart
+		// from "waiting out its own spin-up".
+		factory := func(topic string) (*PollSite, error) {
+			cfg := &PollSiteConfig{
+				StartThreshold: 3,
+				StartTimeout:   time.Second,
+				ReleaseTimeout: time.Millisecond * 10,
+			}
+			if topic == "!slow" {
+				return NewPollSiteWithWAL(newStubVoteStore(), cfg, &slowStartWAL{MemoryWAL: NewMemoryWAL(), delay: spinUpDelay}), nil
+			}
+			return NewPollSiteWithWAL(newStubVoteStore(), cfg, NewMemoryWAL()), nil
+		}
+
+		router := NewRouter(factory)
+		defer router.StopAll()
+
+		go func() {
+			if err := router.Route("!slow", &Vote{voterID: "v1", choice: 1}); err != nil {
+				t.Errorf("unexpected error routing to the slow topic: %v", err)
+			}
+		}()
+		// Give the slow topic's siteFor call a head start so it's the one
+		// holding up progress, not a scheduling fluke.
+		time.Sleep(10 * time.Millisecond)
+
+		start := time.Now()
+		if err := router.Route("!fast", &Vote{voterID: "v1", choice: 1}); err != nil {
+			t.Fatalf("unexpected error routing to the fast topic: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= spinUpDelay {
+			t.Errorf("routing to !fast took %v, expected it not to wait out !slow's spin-up", elapsed)
+		}
+	})
+}
+
+func TestRouter_IdleEviction(t *testing.T) {
+	t.Run("evicts a topic that has been idle and empty for EvictAfter", func(t *testing.T) {
+		router := NewRouterWithConfig(newTestPollSiteFactory(), RouterConfig{
+			EvictAfter: 10 * time.Millisecond,
+		})
+		defer router.StopAll()
+
+		// Push the vote cache past the start threshold and let the release timeout
+		// finish the voting, so the poll site settles back into StateIdle with an
+		// empty cache rather than sitting on a still-pending vote.
+		for i := 0; i < 3; i++ {
+			if err := router.Route("!a", &Vote{voterID: fmt.Sprintf("v%d", i), choice: 1}); err != nil {
+				t.Fatalf("unexpected error routing: %v", err)
+			}
+		}
+
+		deadline := time.Now().Add(200 * time.Millisecond)
+		for time.Now().Before(deadline) {
+			if len(router.Sites()) == 0 {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatal("expected idle topic to be evicted")
+	})
+}
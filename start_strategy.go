@@ -0,0 +1,129 @@
+package chatvotes
+
+import (
+	"sync"
+	"time"
+)
+
+// StartStrategy decides, as each vote arrives while a PollSite is idle, whether
+// enough votes have now accumulated to start a voting. cache holds the votes
+// accumulated so far (not yet committed to the VoteStore); keep is the cache
+// PollSite should retain afterwards, letting a strategy evict votes it no longer
+// considers relevant (e.g. ones outside a rolling window).
+type StartStrategy interface {
+	OnVote(vote *Vote, cache []*Vote) (start bool, keep []*Vote)
+}
+
+// TimeAwareStartStrategy is implemented by a StartStrategy whose OnVote decisions
+// depend on wall-clock time it tracks internally (e.g. RollingWindowStartStrategy's
+// per-voter last-seen timestamps), rather than purely on cache contents. replayWAL
+// type-asserts for it so it can rebuild that internal state using each vote's actual
+// original arrival time instead of the time it happens to replay at; callers that
+// don't need replay-correct timing can just implement StartStrategy.
+type TimeAwareStartStrategy interface {
+	StartStrategy
+	// OnVoteAt is OnVote but with the vote's arrival time supplied explicitly
+	// instead of read off the strategy's own clock. OnVote is equivalent to
+	// OnVoteAt(vote, cache, <the strategy's own clock>).
+	OnVoteAt(vote *Vote, cache []*Vote, at time.Time) (start bool, keep []*Vote)
+}
+
+var _ StartStrategy = CountStartStrategy{}
+
+// CountStartStrategy starts a voting once at least Threshold votes have
+// accumulated since the poll site last went idle. This is chatvotes' original,
+// count-only behavior.
+type CountStartStrategy struct {
+	Threshold int
+}
+
+// OnVote implements StartStrategy.
+func (s CountStartStrategy) OnVote(vote *Vote, cache []*Vote) (start bool, keep []*Vote) {
+	keep = append(cache, vote)
+	return len(keep) >= s.Threshold, keep
+}
+
+var _ StartStrategy = WeightedSumStartStrategy{}
+
+// WeightedSumStartStrategy starts a voting once the summed Weigher-assigned
+// weight of the accumulated votes reaches Threshold, so e.g. moderator or
+// subscriber votes can count for more than one regular viewer's.
+type WeightedSumStartStrategy struct {
+	Weigher   VoteWeigher
+	Threshold float64
+}
+
+// OnVote implements StartStrategy.
+func (s WeightedSumStartStrategy) OnVote(vote *Vote, cache []*Vote) (start bool, keep []*Vote) {
+	keep = append(cache, vote)
+
+	var sum float64
+	for _, v := range keep {
+		sum += s.Weigher.Weight(v)
+	}
+	return sum >= s.Threshold, keep
+}
+
+var _ StartStrategy = (*RollingWindowStartStrategy)(nil)
+
+// RollingWindowStartStrategy starts a voting once at least Threshold distinct
+// voters have voted within the trailing Window, rather than since the poll site
+// last went idle. Use NewRollingWindowStartStrategy to construct one.
+type RollingWindowStartStrategy struct {
+	Threshold int
+	Window    time.Duration
+	// Now returns the current time; defaults to time.Now. Exposed for tests.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+// NewRollingWindowStartStrategy creates a RollingWindowStartStrategy that starts a
+// voting once threshold distinct voters have voted within window.
+func NewRollingWindowStartStrategy(threshold int, window time.Duration) *RollingWindowStartStrategy {
+	return &RollingWindowStartStrategy{
+		Threshold: threshold,
+		Window:    window,
+		lastSeen:  make(map[string]time.Time),
+	}
+}
+
+func (s *RollingWindowStartStrategy) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+var _ TimeAwareStartStrategy = (*RollingWindowStartStrategy)(nil)
+
+// OnVote implements StartStrategy.
+func (s *RollingWindowStartStrategy) OnVote(vote *Vote, cache []*Vote) (start bool, keep []*Vote) {
+	return s.OnVoteAt(vote, cache, s.now())
+}
+
+// OnVoteAt implements TimeAwareStartStrategy.
+func (s *RollingWindowStartStrategy) OnVoteAt(vote *Vote, cache []*Vote, at time.Time) (start bool, keep []*Vote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSeen[vote.voterID] = at
+	cache = append(cache, vote)
+
+	cutoff := at.Add(-s.Window)
+	for voterID, last := range s.lastSeen {
+		if last.Before(cutoff) {
+			delete(s.lastSeen, voterID)
+		}
+	}
+
+	keep = cache[:0]
+	for _, v := range cache {
+		if _, ok := s.lastSeen[v.voterID]; ok {
+			keep = append(keep, v)
+		}
+	}
+
+	return len(s.lastSeen) >= s.Threshold, keep
+}
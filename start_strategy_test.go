@@ -0,0 +1,88 @@
+package chatvotes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountStartStrategy(t *testing.T) {
+	s := CountStartStrategy{Threshold: 3}
+
+	var cache []*Vote
+	for i := 0; i < 2; i++ {
+		start, keep := s.OnVote(&Vote{voterID: "voter", choice: 1}, cache)
+		cache = keep
+		if start {
+			t.Fatalf("expected not to start before reaching the threshold, at vote %d", i+1)
+		}
+	}
+
+	start, keep := s.OnVote(&Vote{voterID: "voter", choice: 1}, cache)
+	if !start {
+		t.Error("expected to start once the threshold is reached")
+	}
+	if len(keep) != 3 {
+		t.Errorf("expected 3 kept votes, got %d", len(keep))
+	}
+}
+
+func TestWeightedSumStartStrategy(t *testing.T) {
+	s := WeightedSumStartStrategy{
+		Weigher: VoteWeigherFunc(func(v *Vote) float64 {
+			if v.metadata["role"] == "moderator" {
+				return 5
+			}
+			return 1
+		}),
+		Threshold: 5,
+	}
+
+	var cache []*Vote
+	start, keep := s.OnVote(&Vote{voterID: "v1", choice: 1}, cache)
+	cache = keep
+	if start {
+		t.Fatal("expected a single regular vote to not reach the threshold")
+	}
+
+	start, _ = s.OnVote(&Vote{voterID: "mod", choice: 1, metadata: map[string]string{"role": "moderator"}}, cache)
+	if !start {
+		t.Error("expected a moderator vote to push the weighted sum over the threshold")
+	}
+}
+
+func TestRollingWindowStartStrategy(t *testing.T) {
+	now := time.Now()
+	s := NewRollingWindowStartStrategy(3, 10*time.Millisecond)
+	s.Now = func() time.Time { return now }
+
+	var cache []*Vote
+	for i, id := range []string{"v1", "v2"} {
+		start, keep := s.OnVote(&Vote{voterID: id, choice: 1}, cache)
+		cache = keep
+		if start {
+			t.Fatalf("expected not to start after %d distinct voters", i+1)
+		}
+	}
+
+	now = now.Add(20 * time.Millisecond)
+	start, keep := s.OnVote(&Vote{voterID: "v3", choice: 1}, cache)
+	cache = keep
+	if start {
+		t.Error("expected earlier voters to have fallen out of the window")
+	}
+
+	now = now.Add(time.Millisecond)
+	start, keep = s.OnVote(&Vote{voterID: "v4", choice: 1}, cache)
+	cache = keep
+	if start {
+		t.Error("expected only 2 distinct voters to be within the window so far")
+	}
+
+	start, keep = s.OnVote(&Vote{voterID: "v5", choice: 1}, cache)
+	if !start {
+		t.Error("expected a 3rd distinct voter within the window to start the voting")
+	}
+	if len(keep) != 3 {
+		t.Errorf("expected v1/v2, which fell out of the window, to be evicted from keep, got %d votes: %v", len(keep), keep)
+	}
+}
@@ -1,5 +1,7 @@
 package chatvotes
 
+import "encoding/json"
+
 // VoteStore is used to keep track of votes. Votes have to be unique,
 // meaning that multiple votes from the same entity should be ignored.
 type VoteStore interface {
@@ -11,8 +13,55 @@ type VoteStore interface {
 
 // Vote is issued by an entity.
 type Vote struct {
-	choice  int
-	voterID string
+	choice   int
+	voterID  string
+	metadata map[string]string
+}
+
+// NewVote creates a Vote for choice cast by voterID. metadata can carry arbitrary
+// context about the voter (badges, subscription months, bits donated, ...) for
+// consumption by a VoteWeigher or a StartStrategy/ReleaseStrategy; it may be nil.
+func NewVote(choice int, voterID string, metadata map[string]string) *Vote {
+	return &Vote{choice: choice, voterID: voterID, metadata: metadata}
+}
+
+// Choice returns the option voterID voted for.
+func (v *Vote) Choice() int {
+	return v.choice
+}
+
+// VoterID returns the identity of the entity that cast the vote.
+func (v *Vote) VoterID() string {
+	return v.voterID
+}
+
+// Metadata returns the arbitrary context attached to the vote, if any.
+func (v *Vote) Metadata() map[string]string {
+	return v.metadata
+}
+
+type voteJSON struct {
+	Choice   int               `json:"choice"`
+	VoterID  string            `json:"voter_id"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler so a Vote can be persisted, e.g. to a WAL,
+// despite its fields being unexported.
+func (v *Vote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(voteJSON{Choice: v.choice, VoterID: v.voterID, Metadata: v.metadata})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the counterpart to MarshalJSON.
+func (v *Vote) UnmarshalJSON(data []byte) error {
+	var aux voteJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	v.choice = aux.Choice
+	v.voterID = aux.VoterID
+	v.metadata = aux.Metadata
+	return nil
 }
 
 // Voting is a snapshot of a finished voting.
@@ -21,6 +70,28 @@ type Voting struct {
 	votes     map[int]int
 }
 
+type votingJSON struct {
+	VoteCount int         `json:"vote_count"`
+	Votes     map[int]int `json:"votes"`
+}
+
+// MarshalJSON implements json.Marshaler so a Voting can be persisted, e.g. to a WAL,
+// despite its fields being unexported.
+func (v Voting) MarshalJSON() ([]byte, error) {
+	return json.Marshal(votingJSON{VoteCount: v.voteCount, Votes: v.votes})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the counterpart to MarshalJSON.
+func (v *Voting) UnmarshalJSON(data []byte) error {
+	var aux votingJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	v.voteCount = aux.VoteCount
+	v.votes = aux.Votes
+	return nil
+}
+
 func (v *Voting) VoteCount() int {
 	return v.voteCount
 }
@@ -0,0 +1,65 @@
+package chatvotes
+
+import "time"
+
+// WALEntryType identifies which state-affecting event a WALEntry records.
+type WALEntryType int
+
+const (
+	// WALEntryVoteCached records a vote added to the idle vote cache, before the
+	// start threshold has been reached.
+	WALEntryVoteCached WALEntryType = iota
+	// WALEntryVoteAccepted records a vote committed to the VoteStore, either because
+	// it arrived during an active voting or because it was in the idle vote cache
+	// when the start threshold was reached.
+	WALEntryVoteAccepted
+	// WALEntryStateTransition records a PollSite state transition.
+	WALEntryStateTransition
+	// WALEntryReleaseTimeout records that the release timeout fired for an active
+	// voting, just before it is finalized.
+	WALEntryReleaseTimeout
+	// WALEntryVotingFinished records the result of a finalized voting.
+	WALEntryVotingFinished
+	// WALEntryCacheReset records the idle vote cache being discarded, e.g. because
+	// the start timeout fired before the start threshold was reached.
+	WALEntryCacheReset
+)
+
+// WALEntry is one state-affecting event recorded to a WAL. Seq is assigned by the
+// WAL on Append and increases monotonically within a single WAL.
+type WALEntry struct {
+	Seq  uint64
+	Type WALEntryType
+
+	Vote       *Vote            `json:",omitempty"`
+	Transition *StateTransition `json:",omitempty"`
+	Voting     *Voting          `json:",omitempty"`
+
+	// Time is Vote's original arrival time, set on WALEntryVoteCached entries only.
+	// replayWAL hands it to a TimeAwareStartStrategy so its replay-time decisions
+	// use the vote's real arrival time rather than the time it happens to replay at.
+	Time time.Time `json:",omitempty"`
+}
+
+// WAL is a write-ahead log that PollSite appends every state-affecting event to, so
+// an in-progress voting can be reconstructed after a crash.
+type WAL interface {
+	// Append writes entry, assigning it the next sequence number, and must not
+	// return until the write is durable.
+	Append(entry WALEntry) error
+	// Replay calls fn once per previously appended entry, in the order they were
+	// written. A partially written tail entry left behind by a crash is detected
+	// via its length prefix or checksum and silently discarded rather than
+	// returned to fn.
+	Replay(fn func(entry WALEntry) error) error
+	// Close releases any resources held by the WAL.
+	Close() error
+}
+
+// Truncator is implemented by WALs that can discard entries up to and including a
+// given sequence number, e.g. once the voting they belong to has been finalized and
+// no longer needs replaying. Callers should type-assert for it rather than relying
+// on it being present, since the minimal WAL interface does not require it.
+type Truncator interface {
+	TruncateBefore(seq uint64) error
+}
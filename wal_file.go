@@ -0,0 +1,219 @@
+package chatvotes
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var _ WAL = (*FileWAL)(nil)
+var _ Truncator = (*FileWAL)(nil)
+
+// walHeaderSize is the length-prefix + checksum that precedes every record:
+// 4 bytes payload length, 4 bytes CRC-32 of the payload.
+const walHeaderSize = 8
+
+// FileWAL is a file-backed WAL. Every entry is written as a length-prefixed,
+// checksummed JSON record so that a tail record left partially written by a crash
+// can be detected and discarded on Replay instead of corrupting recovery.
+type FileWAL struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	seq  uint64
+}
+
+// NewFileWAL opens (creating if necessary) the WAL file at path for appending, and
+// replays it once to recover the last sequence number so Append continues the
+// sequence across restarts.
+func NewFileWAL(path string) (*FileWAL, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &FileWAL{path: path, file: f}
+	if err := w.Replay(func(entry WALEntry) error {
+		w.seq = entry.Seq
+		return nil
+	}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Append implements WAL.
+func (w *FileWAL) Append(entry WALEntry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	entry.Seq = w.seq
+
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, walHeaderSize)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+
+	if _, err := w.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if _, err := w.file.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.file.Write(payload); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Replay implements WAL. It reads the file from a fresh handle so it can be called
+// safely both during recovery and concurrently with Append.
+func (w *FileWAL) Replay(fn func(entry WALEntry) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.Open(w.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		header := make([]byte, walHeaderSize)
+		if _, err := io.ReadFull(r, header); err != nil {
+			// EOF, or a header shorter than walHeaderSize left by a crash
+			// mid-write: either way there is nothing more to replay.
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		checksum := binary.BigEndian.Uint32(header[4:8])
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			// A payload shorter than its own header announced: the tail entry was
+			// only partially written before the crash. Discard it.
+			return nil
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			// A full-length but corrupt tail entry. Discard it rather than risk
+			// replaying garbage.
+			return nil
+		}
+
+		var entry WALEntry
+		if err := json.Unmarshal(payload, &entry); err != nil {
+			return nil
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// TruncateBefore implements Truncator by rewriting the file to keep only entries
+// with Seq > seq.
+func (w *FileWAL) TruncateBefore(seq uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	keepErr := func() error {
+		f, err := os.Open(w.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r := bufio.NewReader(f)
+		for {
+			header := make([]byte, walHeaderSize)
+			if _, err := io.ReadFull(r, header); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil
+				}
+				return err
+			}
+			length := binary.BigEndian.Uint32(header[0:4])
+			checksum := binary.BigEndian.Uint32(header[4:8])
+
+			payload := make([]byte, length)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return nil
+			}
+			if crc32.ChecksumIEEE(payload) != checksum {
+				return nil
+			}
+
+			var entry WALEntry
+			if err := json.Unmarshal(payload, &entry); err != nil {
+				return nil
+			}
+			if entry.Seq <= seq {
+				continue
+			}
+			if _, err := tmp.Write(header); err != nil {
+				return err
+			}
+			if _, err := tmp.Write(payload); err != nil {
+				return err
+			}
+		}
+	}()
+	if keepErr != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return keepErr
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := w.file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	return nil
+}
+
+// Close implements WAL.
+func (w *FileWAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
@@ -0,0 +1,65 @@
+package chatvotes
+
+import "sync"
+
+var _ WAL = (*MemoryWAL)(nil)
+var _ Truncator = (*MemoryWAL)(nil)
+
+// MemoryWAL is an in-memory WAL. It is safe for concurrent use and is intended for
+// use in tests, where a file-backed WAL would be unnecessary overhead.
+type MemoryWAL struct {
+	mu      sync.Mutex
+	seq     uint64
+	entries []WALEntry
+}
+
+// NewMemoryWAL creates an empty MemoryWAL.
+func NewMemoryWAL() *MemoryWAL {
+	return &MemoryWAL{}
+}
+
+// Append implements WAL.
+func (m *MemoryWAL) Append(entry WALEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	entry.Seq = m.seq
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Replay implements WAL.
+func (m *MemoryWAL) Replay(fn func(entry WALEntry) error) error {
+	m.mu.Lock()
+	entries := make([]WALEntry, len(m.entries))
+	copy(entries, m.entries)
+	m.mu.Unlock()
+
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements WAL. It is a no-op for a MemoryWAL.
+func (m *MemoryWAL) Close() error {
+	return nil
+}
+
+// TruncateBefore implements Truncator.
+func (m *MemoryWAL) TruncateBefore(seq uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.entries[:0]
+	for _, entry := range m.entries {
+		if entry.Seq > seq {
+			kept = append(kept, entry)
+		}
+	}
+	m.entries = kept
+	return nil
+}
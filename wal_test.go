@@ -0,0 +1,191 @@
+package chatvotes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryWAL(t *testing.T) {
+	t.Run("replays entries in append order with assigned sequence numbers", func(t *testing.T) {
+		wal := NewMemoryWAL()
+		vote := &Vote{choice: 1, voterID: "voter"}
+
+		if err := wal.Append(WALEntry{Type: WALEntryVoteCached, Vote: vote}); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+		if err := wal.Append(WALEntry{Type: WALEntryVoteAccepted, Vote: vote}); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+
+		var replayed []WALEntry
+		if err := wal.Replay(func(entry WALEntry) error {
+			replayed = append(replayed, entry)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+
+		if len(replayed) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(replayed))
+		}
+		if replayed[0].Seq != 1 || replayed[1].Seq != 2 {
+			t.Errorf("expected sequence numbers 1 and 2, got %d and %d", replayed[0].Seq, replayed[1].Seq)
+		}
+	})
+
+	t.Run("truncate before drops entries up to and including seq", func(t *testing.T) {
+		wal := NewMemoryWAL()
+		for i := 0; i < 3; i++ {
+			if err := wal.Append(WALEntry{Type: WALEntryStateTransition}); err != nil {
+				t.Fatalf("unexpected error appending: %v", err)
+			}
+		}
+
+		if err := wal.TruncateBefore(2); err != nil {
+			t.Fatalf("unexpected error truncating: %v", err)
+		}
+
+		var replayed []WALEntry
+		if err := wal.Replay(func(entry WALEntry) error {
+			replayed = append(replayed, entry)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+
+		if len(replayed) != 1 || replayed[0].Seq != 3 {
+			t.Fatalf("expected only entry with seq 3 to remain, got %+v", replayed)
+		}
+	})
+}
+
+func TestFileWAL(t *testing.T) {
+	t.Run("replays entries written in a previous session", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "wal")
+
+		wal, err := NewFileWAL(path)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal: %v", err)
+		}
+		vote := &Vote{choice: 2, voterID: "voter"}
+		if err := wal.Append(WALEntry{Type: WALEntryVoteCached, Vote: vote}); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+		if err := wal.Close(); err != nil {
+			t.Fatalf("unexpected error closing wal: %v", err)
+		}
+
+		reopened, err := NewFileWAL(path)
+		if err != nil {
+			t.Fatalf("unexpected error reopening wal: %v", err)
+		}
+		defer reopened.Close()
+
+		var replayed []WALEntry
+		if err := reopened.Replay(func(entry WALEntry) error {
+			replayed = append(replayed, entry)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+
+		if len(replayed) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(replayed))
+		}
+		if replayed[0].Vote.choice != 2 || replayed[0].Vote.voterID != "voter" {
+			t.Errorf("expected recovered vote to match, got %+v", replayed[0].Vote)
+		}
+
+		// Continuing to append after reopening must keep sequence numbers
+		// monotonic across the restart.
+		if err := reopened.Append(WALEntry{Type: WALEntryVoteCached, Vote: vote}); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+		replayed = nil
+		if err := reopened.Replay(func(entry WALEntry) error {
+			replayed = append(replayed, entry)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+		if len(replayed) != 2 || replayed[1].Seq != 2 {
+			t.Fatalf("expected second entry to have seq 2, got %+v", replayed)
+		}
+	})
+
+	t.Run("discards a partially written tail entry", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "wal")
+
+		wal, err := NewFileWAL(path)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal: %v", err)
+		}
+		if err := wal.Append(WALEntry{Type: WALEntryStateTransition}); err != nil {
+			t.Fatalf("unexpected error appending: %v", err)
+		}
+		if err := wal.Close(); err != nil {
+			t.Fatalf("unexpected error closing wal: %v", err)
+		}
+
+		// Simulate a crash mid-write by appending a truncated header for a
+		// third-party record that was never fully written.
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal file: %v", err)
+		}
+		if _, err := f.Write([]byte{0, 0, 0, 100}); err != nil {
+			t.Fatalf("unexpected error writing partial tail: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("unexpected error closing wal file: %v", err)
+		}
+
+		reopened, err := NewFileWAL(path)
+		if err != nil {
+			t.Fatalf("unexpected error reopening wal: %v", err)
+		}
+		defer reopened.Close()
+
+		var replayed []WALEntry
+		if err := reopened.Replay(func(entry WALEntry) error {
+			replayed = append(replayed, entry)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+		if len(replayed) != 1 {
+			t.Fatalf("expected the partial tail entry to be discarded, got %d entries", len(replayed))
+		}
+	})
+
+	t.Run("truncate before drops entries up to and including seq", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "wal")
+
+		wal, err := NewFileWAL(path)
+		if err != nil {
+			t.Fatalf("unexpected error opening wal: %v", err)
+		}
+		defer wal.Close()
+
+		for i := 0; i < 3; i++ {
+			if err := wal.Append(WALEntry{Type: WALEntryStateTransition}); err != nil {
+				t.Fatalf("unexpected error appending: %v", err)
+			}
+		}
+		if err := wal.TruncateBefore(2); err != nil {
+			t.Fatalf("unexpected error truncating: %v", err)
+		}
+
+		var replayed []WALEntry
+		if err := wal.Replay(func(entry WALEntry) error {
+			replayed = append(replayed, entry)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+		if len(replayed) != 1 || replayed[0].Seq != 3 {
+			t.Fatalf("expected only entry with seq 3 to remain, got %+v", replayed)
+		}
+	})
+}
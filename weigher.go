@@ -0,0 +1,16 @@
+package chatvotes
+
+// VoteWeigher assigns a weight to a vote, e.g. so moderators, subscribers, or
+// bit-donors count for more than one regular viewer. It is consulted by
+// weight-aware StartStrategy implementations such as WeightedSumStartStrategy.
+type VoteWeigher interface {
+	Weight(vote *Vote) float64
+}
+
+// VoteWeigherFunc adapts a plain function to a VoteWeigher.
+type VoteWeigherFunc func(vote *Vote) float64
+
+// Weight implements VoteWeigher.
+func (f VoteWeigherFunc) Weight(vote *Vote) float64 {
+	return f(vote)
+}